@@ -0,0 +1,104 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userloginstate
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logrusHandler is a minimal slog.Handler that forwards records to a
+// logrus.FieldLogger. It exists solely to let callers that still construct
+// ServiceConfig with a logrus logger keep working while the rest of Teleport
+// migrates to slog; new callers should set ServiceConfig.Logger directly.
+type logrusHandler struct {
+	log   logrus.FieldLogger
+	attrs []slog.Attr
+}
+
+// newLogrusHandler wraps log so it can be used as the backing slog.Handler
+// for a *slog.Logger.
+func newLogrusHandler(log logrus.FieldLogger) slog.Handler {
+	return &logrusHandler{log: log}
+}
+
+// levelEnabler is implemented by *logrus.Logger and *logrus.Entry, letting
+// Enabled defer to the wrapped logger's own level filtering instead of
+// always doing the work to build and hand off a record that would just be
+// dropped.
+type levelEnabler interface {
+	IsLevelEnabled(logrus.Level) bool
+}
+
+func (h *logrusHandler) Enabled(_ context.Context, level slog.Level) bool {
+	enabler, ok := h.log.(levelEnabler)
+	if !ok {
+		return true
+	}
+	return enabler.IsLevelEnabled(slogToLogrusLevel(level))
+}
+
+func slogToLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	default:
+		return logrus.DebugLevel
+	}
+}
+
+func (h *logrusHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, record.NumAttrs()+len(h.attrs))
+	for _, a := range h.attrs {
+		fields[a.Key] = a.Value.Any()
+	}
+	record.Attrs(func(a slog.Attr) bool {
+		fields[a.Key] = a.Value.Any()
+		return true
+	})
+
+	entry := h.log.WithFields(fields)
+	switch {
+	case record.Level >= slog.LevelError:
+		entry.Error(record.Message)
+	case record.Level >= slog.LevelWarn:
+		entry.Warn(record.Message)
+	case record.Level >= slog.LevelInfo:
+		entry.Info(record.Message)
+	default:
+		entry.Debug(record.Message)
+	}
+	return nil
+}
+
+func (h *logrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logrusHandler{log: h.log, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *logrusHandler) WithGroup(_ string) slog.Handler {
+	// Groups aren't used by this package; return the handler unchanged
+	// rather than silently dropping attributes added afterwards.
+	return h
+}
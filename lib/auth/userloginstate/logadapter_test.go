@@ -0,0 +1,90 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userloginstate
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogToLogrusLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  logrus.Level
+	}{
+		{slog.LevelDebug, logrus.DebugLevel},
+		{slog.LevelInfo, logrus.InfoLevel},
+		{slog.LevelWarn, logrus.WarnLevel},
+		{slog.LevelError, logrus.ErrorLevel},
+		{slog.LevelError + 4, logrus.ErrorLevel},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, slogToLogrusLevel(tt.level))
+	}
+}
+
+func TestLogrusHandler_Enabled(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	logger.SetLevel(logrus.WarnLevel)
+
+	handler := newLogrusHandler(logger)
+	ctx := context.Background()
+	require.False(t, handler.Enabled(ctx, slog.LevelInfo))
+	require.True(t, handler.Enabled(ctx, slog.LevelWarn))
+	require.True(t, handler.Enabled(ctx, slog.LevelError))
+}
+
+func TestLogrusHandler_Handle(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+
+	log := slog.New(newLogrusHandler(logger))
+	log.Info("user login state updated", "resource", "alice")
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Equal(t, logrus.InfoLevel, entry.Level)
+	require.Equal(t, "user login state updated", entry.Message)
+	require.Equal(t, "alice", entry.Data["resource"])
+}
+
+func TestLogrusHandler_WithAttrs(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+
+	log := slog.New(newLogrusHandler(logger)).With("caller", "UpsertUserLoginState")
+	log.Warn("failed to emit audit event", "error", "boom")
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Equal(t, logrus.WarnLevel, entry.Level)
+	require.Equal(t, "UpsertUserLoginState", entry.Data["caller"])
+	require.Equal(t, "boom", entry.Data["error"])
+}
+
+func TestLogrusHandler_WithGroup(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	handler := newLogrusHandler(logger)
+	require.Same(t, handler, handler.WithGroup("ignored"))
+}
@@ -20,23 +20,34 @@ package userloginstate
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/gravitational/trace"
 	"github.com/jonboulle/clockwork"
 	"github.com/sirupsen/logrus"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	userloginstatev1 "github.com/gravitational/teleport/api/gen/proto/go/teleport/userloginstate/v1"
 	"github.com/gravitational/teleport/api/types"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	headerv1 "github.com/gravitational/teleport/api/types/header/v1"
 	conv "github.com/gravitational/teleport/api/types/userloginstate/convert/v1"
 	"github.com/gravitational/teleport/lib/authz"
+	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/services"
 )
 
 // ServiceConfig is the service config for the Access Lists gRPC service.
 type ServiceConfig struct {
 	// Logger is the logger to use.
-	Logger logrus.FieldLogger
+	Logger *slog.Logger
+
+	// LegacyLogger allows constructing a Service with a logrus logger during
+	// the migration to slog. Ignored if Logger is set.
+	//
+	// Deprecated: use Logger instead.
+	LegacyLogger logrus.FieldLogger
 
 	// Authorizer is the authorizer to use.
 	Authorizer authz.Authorizer
@@ -44,6 +55,14 @@ type ServiceConfig struct {
 	// UserLoginStates is the user login state service to use.
 	UserLoginStates services.UserLoginStates
 
+	// Emitter is the event emitter used to emit audit events for
+	// UserLoginState mutations. If nil, no audit events are emitted.
+	Emitter events.Emitter
+
+	// Events is used to watch for changes to user login states, backing
+	// WatchUserLoginStates.
+	Events types.Events
+
 	// Clock is the clock.
 	Clock clockwork.Clock
 }
@@ -57,8 +76,17 @@ func (c *ServiceConfig) checkAndSetDefaults() error {
 		return trace.BadParameter("user login states service is missing")
 	}
 
+	if c.Events == nil {
+		return trace.BadParameter("events service is missing")
+	}
+
 	if c.Logger == nil {
-		c.Logger = logrus.WithField(trace.Component, "user_login_state_crud_service")
+		switch {
+		case c.LegacyLogger != nil:
+			c.Logger = slog.New(newLogrusHandler(c.LegacyLogger))
+		default:
+			c.Logger = slog.With(trace.Component, "user_login_state_crud_service")
+		}
 	}
 
 	if c.Clock == nil {
@@ -71,9 +99,11 @@ func (c *ServiceConfig) checkAndSetDefaults() error {
 type Service struct {
 	userloginstatev1.UnimplementedUserLoginStateServiceServer
 
-	log             logrus.FieldLogger
+	log             *slog.Logger
 	authorizer      authz.Authorizer
 	userLoginStates services.UserLoginStates
+	emitter         events.Emitter
+	events          types.Events
 	clock           clockwork.Clock
 }
 
@@ -87,18 +117,225 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		log:             cfg.Logger,
 		authorizer:      cfg.Authorizer,
 		userLoginStates: cfg.UserLoginStates,
+		emitter:         cfg.Emitter,
+		events:          cfg.Events,
 		clock:           cfg.Clock,
 	}, nil
 }
 
+// userLoginStatesCacheInvalidator is implemented by UserLoginStates
+// decorators that maintain a local cache (e.g. services.CachingUserLoginStates)
+// and need to be told about writes made through this Service so reads
+// through the cache observe them immediately.
+type userLoginStatesCacheInvalidator interface {
+	InvalidateUserLoginState(name string)
+	InvalidateAllUserLoginStates()
+}
+
+// invalidateCache evicts name from the configured UserLoginStates cache, if
+// it is a caching decorator. It is a no-op otherwise.
+func (s *Service) invalidateCache(name string) {
+	if invalidator, ok := s.userLoginStates.(userLoginStatesCacheInvalidator); ok {
+		invalidator.InvalidateUserLoginState(name)
+	}
+}
+
+// invalidateAllCache evicts every entry from the configured UserLoginStates
+// cache, if it is a caching decorator. It is a no-op otherwise.
+func (s *Service) invalidateAllCache() {
+	if invalidator, ok := s.userLoginStates.(userLoginStatesCacheInvalidator); ok {
+		invalidator.InvalidateAllUserLoginStates()
+	}
+}
+
+// userLoginStatesUncachedReader is implemented by UserLoginStates decorators
+// that can serve a read straight from the backend, bypassing any cache.
+type userLoginStatesUncachedReader interface {
+	GetUserLoginStateUncached(ctx context.Context, name string) (types.UserLoginState, error)
+}
+
+// getUserLoginStateForDiff reads the current state of name for use as the
+// "prior" side of an audit-event role/trait diff. It bypasses the configured
+// UserLoginStates cache, if any, since a cached value up to the cache's TTL
+// old would make AddedRoles/RemovedRoles/AddedTraits/RemovedTraits wrong
+// around a write that races a cache entry's expiry.
+func (s *Service) getUserLoginStateForDiff(ctx context.Context, name string) (types.UserLoginState, error) {
+	if reader, ok := s.userLoginStates.(userLoginStatesUncachedReader); ok {
+		return reader.GetUserLoginStateUncached(ctx, name)
+	}
+	return s.userLoginStates.GetUserLoginState(ctx, name)
+}
+
+// requestLogger returns a logger scoped to a single RPC invocation, carrying
+// the calling RPC (caller), the authz verb it's enforcing, the acting user
+// (when available), and the request's trace ID (when available). Using this
+// instead of s.log directly keeps log lines for a given call correlatable in
+// a structured log pipeline.
+func (s *Service) requestLogger(ctx context.Context, caller, verb string) *slog.Logger {
+	log := s.log.With("caller", caller, "verb", verb)
+
+	if identity, err := authz.UserFromContext(ctx); err == nil {
+		log = log.With("user", identity.GetIdentity().Username)
+	}
+
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		log = log.With("trace_id", sc.TraceID().String())
+	}
+
+	return log
+}
+
+// emitEvent emits an audit event if an emitter has been configured, logging
+// (rather than returning) any failure so that a broken audit log backend
+// never blocks a UserLoginState mutation from completing.
+func (s *Service) emitEvent(ctx context.Context, log *slog.Logger, e apievents.AuditEvent) {
+	if s.emitter == nil {
+		return
+	}
+
+	if err := s.emitter.EmitAuditEvent(ctx, e); err != nil {
+		log.WarnContext(ctx, "Failed to emit user login state audit event.", "error", err, "event_type", e.GetType())
+	}
+}
+
+// rolesAndTraitsDiff describes the change in roles and traits between the
+// previous and new state of a user login state, for inclusion in audit
+// events.
+type rolesAndTraitsDiff struct {
+	addedRoles    []string
+	removedRoles  []string
+	addedTraits   map[string][]string
+	removedTraits map[string][]string
+}
+
+func diffRolesAndTraits(prior, updated types.UserLoginState) rolesAndTraitsDiff {
+	var diff rolesAndTraitsDiff
+
+	var priorRoles, updatedRoles []string
+	var priorTraits, updatedTraits map[string][]string
+	if prior != nil {
+		priorRoles = prior.GetRoles()
+		priorTraits = prior.GetTraits()
+	}
+	if updated != nil {
+		updatedRoles = updated.GetRoles()
+		updatedTraits = updated.GetTraits()
+	}
+
+	diff.addedRoles = stringsNotIn(updatedRoles, priorRoles)
+	diff.removedRoles = stringsNotIn(priorRoles, updatedRoles)
+	diff.addedTraits = traitsNotIn(updatedTraits, priorTraits)
+	diff.removedTraits = traitsNotIn(priorTraits, updatedTraits)
+
+	return diff
+}
+
+func stringsNotIn(set, exclude []string) []string {
+	excluded := make(map[string]struct{}, len(exclude))
+	for _, s := range exclude {
+		excluded[s] = struct{}{}
+	}
+
+	var out []string
+	for _, s := range set {
+		if _, ok := excluded[s]; !ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func traitsNotIn(set, exclude map[string][]string) map[string][]string {
+	out := make(map[string][]string)
+	for k, v := range set {
+		diff := stringsNotIn(v, exclude[k])
+		if len(diff) > 0 {
+			out[k] = diff
+		}
+	}
+	return out
+}
+
+// defaultPageSize is the page size used internally when looping over
+// ListUserLoginStates to serve an unpaginated, full-listing contract.
+const defaultPageSize = 200
+
+// listAllUserLoginStates pages through list, a ListUserLoginStates-shaped
+// call, until the returned next page token is empty, invoking onItem for
+// every result in page order. It factors out the page-at-a-time walk shared
+// by GetUserLoginStates' and WatchUserLoginStates' full-listing needs, which
+// differ only in what they do with each item (proto conversion, and
+// optionally per-name filtering).
+func listAllUserLoginStates(
+	ctx context.Context,
+	list func(ctx context.Context, pageSize int, pageToken string) ([]types.UserLoginState, string, error),
+	onItem func(types.UserLoginState),
+) error {
+	var pageToken string
+	for {
+		results, nextPageToken, err := list(ctx, defaultPageSize, pageToken)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+
+		for _, r := range results {
+			onItem(r)
+		}
+
+		pageToken = nextPageToken
+		if pageToken == "" {
+			return nil
+		}
+	}
+}
+
 // GetUserLoginStates returns a list of all user login states.
+//
+// Deprecated: use ListUserLoginStates instead. This is kept as a thin wrapper
+// around ListUserLoginStates for backward compatibility with older callers
+// and clients that have not yet adopted pagination.
 func (s *Service) GetUserLoginStates(ctx context.Context, _ *userloginstatev1.GetUserLoginStatesRequest) (*userloginstatev1.GetUserLoginStatesResponse, error) {
-	_, err := authz.AuthorizeWithVerbs(ctx, s.log, s.authorizer, true, types.KindUserLoginState, types.VerbRead, types.VerbList)
+	log := s.requestLogger(ctx, "GetUserLoginStates", "list")
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbRead, types.VerbList)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	results, err := s.userLoginStates.GetUserLoginStates(ctx)
+	var ulsList []*userloginstatev1.UserLoginState
+	// Call the underlying service directly rather than looping over the
+	// ListUserLoginStates RPC method: the latter would re-run
+	// AuthorizeWithVerbs and build a fresh request-scoped logger on every
+	// page, which adds up on clusters with tens of thousands of users.
+	err = listAllUserLoginStates(ctx, func(ctx context.Context, pageSize int, pageToken string) ([]types.UserLoginState, string, error) {
+		return s.userLoginStates.ListUserLoginStates(ctx, pageSize, pageToken, services.UserLoginStatesFilter{})
+	}, func(uls types.UserLoginState) {
+		ulsList = append(ulsList, conv.ToProto(uls))
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &userloginstatev1.GetUserLoginStatesResponse{
+		UserLoginStates: ulsList,
+	}, nil
+}
+
+// ListUserLoginStates returns a page of user login states, optionally
+// filtered by search keywords, role name, or the presence of a trait.
+func (s *Service) ListUserLoginStates(ctx context.Context, req *userloginstatev1.ListUserLoginStatesRequest) (*userloginstatev1.ListUserLoginStatesResponse, error) {
+	log := s.requestLogger(ctx, "ListUserLoginStates", "list")
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbRead, types.VerbList)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	results, nextPageToken, err := s.userLoginStates.ListUserLoginStates(ctx, int(req.GetPageSize()), req.GetPageToken(), services.UserLoginStatesFilter{
+		SearchKeywords: req.GetSearchKeywords(),
+		RoleName:       req.GetRoleName(),
+		HasTrait:       req.GetHasTrait(),
+	})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -108,14 +345,17 @@ func (s *Service) GetUserLoginStates(ctx context.Context, _ *userloginstatev1.Ge
 		ulsList[i] = conv.ToProto(r)
 	}
 
-	return &userloginstatev1.GetUserLoginStatesResponse{
+	return &userloginstatev1.ListUserLoginStatesResponse{
 		UserLoginStates: ulsList,
+		NextPageToken:   nextPageToken,
 	}, nil
 }
 
 // GetUserLoginState returns the specified user login state resource.
 func (s *Service) GetUserLoginState(ctx context.Context, req *userloginstatev1.GetUserLoginStateRequest) (*userloginstatev1.UserLoginState, error) {
-	_, err := authz.AuthorizeWithVerbs(ctx, s.log, s.authorizer, true, types.KindUserLoginState, types.VerbRead)
+	log := s.requestLogger(ctx, "GetUserLoginState", "read").With("resource", req.GetName())
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbRead)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -128,9 +368,137 @@ func (s *Service) GetUserLoginState(ctx context.Context, req *userloginstatev1.G
 	return conv.ToProto(result), nil
 }
 
+// WatchUserLoginStates streams user login state changes to the caller. It
+// sends an initial OpInit event carrying the current snapshot (subject to the
+// same read authz check as GetUserLoginStates), followed by a PUT event for
+// every subsequent create/update and a DELETE event for every removal. If
+// req.Names is non-empty, events are filtered to those user names.
+func (s *Service) WatchUserLoginStates(req *userloginstatev1.WatchUserLoginStatesRequest, stream userloginstatev1.UserLoginStateService_WatchUserLoginStatesServer) error {
+	ctx := stream.Context()
+	log := s.requestLogger(ctx, "WatchUserLoginStates", "list")
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbRead, types.VerbList)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	wantName := nameFilter(req.GetNames())
+
+	watcher, err := s.events.NewWatcher(ctx, types.Watch{
+		Name: "user-login-state-watch",
+		Kinds: []types.WatchKind{
+			{Kind: types.KindUserLoginState},
+		},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer watcher.Close()
+
+	select {
+	case event := <-watcher.Events():
+		if event.Type != types.OpInit {
+			return trace.ConnectionProblem(nil, "unexpected event type %v while waiting for watcher init", event.Type)
+		}
+	case <-watcher.Done():
+		return trace.Wrap(watcher.Error())
+	case <-ctx.Done():
+		return nil
+	}
+
+	// Page through the backend rather than fetching every login state in a
+	// single unpaginated GetUserLoginStates call, so each backend read stays
+	// bounded. The snapshot itself still collects every matching login state
+	// in memory and sends them in one message.
+	var snapshot []*userloginstatev1.UserLoginState
+	err = listAllUserLoginStates(ctx, func(ctx context.Context, pageSize int, pageToken string) ([]types.UserLoginState, string, error) {
+		return s.userLoginStates.ListUserLoginStates(ctx, pageSize, pageToken, services.UserLoginStatesFilter{})
+	}, func(uls types.UserLoginState) {
+		if wantName(uls.GetName()) {
+			snapshot = append(snapshot, conv.ToProto(uls))
+		}
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := stream.Send(&userloginstatev1.WatchUserLoginStatesResponse{
+		Type:            userloginstatev1.Op_OP_INIT,
+		UserLoginStates: snapshot,
+	}); err != nil {
+		return trace.Wrap(err)
+	}
+
+	for {
+		select {
+		case event := <-watcher.Events():
+			opType, uls, err := toWatchEvent(event)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+
+			if !wantName(uls.GetName()) {
+				continue
+			}
+
+			if err := stream.Send(&userloginstatev1.WatchUserLoginStatesResponse{
+				Type:            opType,
+				UserLoginStates: []*userloginstatev1.UserLoginState{uls},
+			}); err != nil {
+				return trace.Wrap(err)
+			}
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// nameFilter returns a predicate matching the user names WatchUserLoginStates
+// should emit events for. An empty names list matches every name.
+func nameFilter(names []string) func(name string) bool {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return func(name string) bool {
+		if len(set) == 0 {
+			return true
+		}
+		_, ok := set[name]
+		return ok
+	}
+}
+
+// toWatchEvent converts a backend resource event into the gRPC op type and
+// proto representation used by WatchUserLoginStates.
+func toWatchEvent(event types.Event) (userloginstatev1.Op, *userloginstatev1.UserLoginState, error) {
+	switch event.Type {
+	case types.OpPut:
+		uls, ok := event.Resource.(types.UserLoginState)
+		if !ok {
+			return 0, nil, trace.BadParameter("unexpected resource type %T for put event", event.Resource)
+		}
+		return userloginstatev1.Op_OP_PUT, conv.ToProto(uls), nil
+	case types.OpDelete:
+		return userloginstatev1.Op_OP_DELETE, &userloginstatev1.UserLoginState{
+			Kind: types.KindUserLoginState,
+			Metadata: &headerv1.Metadata{
+				Name: event.Resource.GetName(),
+			},
+		}, nil
+	default:
+		return 0, nil, trace.BadParameter("unexpected event type %v", event.Type)
+	}
+}
+
 // UpsertUserLoginState creates or updates a user login state resource.
 func (s *Service) UpsertUserLoginState(ctx context.Context, req *userloginstatev1.UpsertUserLoginStateRequest) (*userloginstatev1.UserLoginState, error) {
-	_, err := authz.AuthorizeWithVerbs(ctx, s.log, s.authorizer, true, types.KindUserLoginState, types.VerbCreate, types.VerbUpdate)
+	log := s.requestLogger(ctx, "UpsertUserLoginState", "create/update").With("resource", req.GetUserLoginState().GetMetadata().GetName())
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbCreate, types.VerbUpdate)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -140,32 +508,94 @@ func (s *Service) UpsertUserLoginState(ctx context.Context, req *userloginstatev
 		return nil, trace.Wrap(err)
 	}
 
+	prior, err := s.getUserLoginStateForDiff(ctx, newUls.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+
 	responseUls, err := s.userLoginStates.UpsertUserLoginState(ctx, newUls)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	s.invalidateCache(responseUls.GetName())
+
+	diff := diffRolesAndTraits(prior, responseUls)
+	userMetadata := actorMetadata(ctx, log)
+	resourceMetadata := apievents.ResourceMetadata{
+		Name: responseUls.GetName(),
+	}
+
+	if prior == nil {
+		s.emitEvent(ctx, log, &apievents.UserLoginStateCreate{
+			Metadata: apievents.Metadata{
+				Type: events.UserLoginStateCreateEvent,
+				Code: events.UserLoginStateCreateCode,
+			},
+			UserMetadata:     userMetadata,
+			ResourceMetadata: resourceMetadata,
+			AddedRoles:       diff.addedRoles,
+			AddedTraits:      diff.addedTraits,
+		})
+	} else {
+		s.emitEvent(ctx, log, &apievents.UserLoginStateUpdate{
+			Metadata: apievents.Metadata{
+				Type: events.UserLoginStateUpdateEvent,
+				Code: events.UserLoginStateUpdateCode,
+			},
+			UserMetadata:     userMetadata,
+			ResourceMetadata: resourceMetadata,
+			AddedRoles:       diff.addedRoles,
+			RemovedRoles:     diff.removedRoles,
+			AddedTraits:      diff.addedTraits,
+			RemovedTraits:    diff.removedTraits,
+		})
+	}
 
 	return conv.ToProto(responseUls), nil
 }
 
 // DeleteUserLoginState removes the specified user login state resource.
 func (s *Service) DeleteUserLoginState(ctx context.Context, req *userloginstatev1.DeleteUserLoginStateRequest) (*emptypb.Empty, error) {
-	_, err := authz.AuthorizeWithVerbs(ctx, s.log, s.authorizer, true, types.KindUserLoginState, types.VerbDelete)
+	log := s.requestLogger(ctx, "DeleteUserLoginState", "delete").With("resource", req.GetName())
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbDelete)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
+	prior, err := s.getUserLoginStateForDiff(ctx, req.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+
 	err = s.userLoginStates.DeleteUserLoginState(ctx, req.GetName())
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	s.invalidateCache(req.GetName())
+
+	diff := diffRolesAndTraits(prior, nil)
+	s.emitEvent(ctx, log, &apievents.UserLoginStateDelete{
+		Metadata: apievents.Metadata{
+			Type: events.UserLoginStateDeleteEvent,
+			Code: events.UserLoginStateDeleteCode,
+		},
+		UserMetadata: actorMetadata(ctx, log),
+		ResourceMetadata: apievents.ResourceMetadata{
+			Name: req.GetName(),
+		},
+		RemovedRoles:  diff.removedRoles,
+		RemovedTraits: diff.removedTraits,
+	})
 
 	return &emptypb.Empty{}, nil
 }
 
 // DeleteAllUserLoginStates removes all user login states.
 func (s *Service) DeleteAllUserLoginStates(ctx context.Context, _ *userloginstatev1.DeleteAllUserLoginStatesRequest) (*emptypb.Empty, error) {
-	_, err := authz.AuthorizeWithVerbs(ctx, s.log, s.authorizer, true, types.KindUserLoginState, types.VerbDelete)
+	log := s.requestLogger(ctx, "DeleteAllUserLoginStates", "delete")
+
+	_, err := authz.AuthorizeWithVerbs(ctx, log, s.authorizer, true, types.KindUserLoginState, types.VerbDelete)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -174,6 +604,29 @@ func (s *Service) DeleteAllUserLoginStates(ctx context.Context, _ *userloginstat
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	s.invalidateAllCache()
+
+	s.emitEvent(ctx, log, &apievents.UserLoginStatesDeleteAll{
+		Metadata: apievents.Metadata{
+			Type: events.UserLoginStatesDeleteAllEvent,
+			Code: events.UserLoginStatesDeleteAllCode,
+		},
+		UserMetadata: actorMetadata(ctx, log),
+	})
 
 	return &emptypb.Empty{}, nil
 }
+
+// actorMetadata builds audit event user metadata for the identity performing
+// the request, falling back to an empty UserMetadata if the identity can't be
+// extracted from ctx (this should not normally happen, since the RPC handler
+// has already authorized the caller).
+func actorMetadata(ctx context.Context, log *slog.Logger) apievents.UserMetadata {
+	identity, err := authz.UserFromContext(ctx)
+	if err != nil {
+		log.WarnContext(ctx, "Failed to determine acting identity for audit event.", "error", err)
+		return apievents.UserMetadata{}
+	}
+
+	return identity.GetIdentity().GetUserMetadata()
+}
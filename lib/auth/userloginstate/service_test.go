@@ -0,0 +1,241 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userloginstate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	userloginstatev1 "github.com/gravitational/teleport/api/gen/proto/go/teleport/userloginstate/v1"
+	"github.com/gravitational/teleport/api/types"
+)
+
+func newTestUserLoginState(t *testing.T, name string) types.UserLoginState {
+	t.Helper()
+	uls, err := types.NewUserLoginState(types.Metadata{Name: name}, types.UserLoginStateSpec{})
+	require.NoError(t, err)
+	return uls
+}
+
+func newTestUserLoginStateWithRolesAndTraits(t *testing.T, name string, roles []string, traits map[string][]string) types.UserLoginState {
+	t.Helper()
+	uls, err := types.NewUserLoginState(types.Metadata{Name: name}, types.UserLoginStateSpec{
+		Roles:  roles,
+		Traits: traits,
+	})
+	require.NoError(t, err)
+	return uls
+}
+
+func TestStringsNotIn(t *testing.T) {
+	tests := []struct {
+		name         string
+		set, exclude []string
+		want         []string
+	}{
+		{name: "no overlap", set: []string{"a", "b"}, exclude: nil, want: []string{"a", "b"}},
+		{name: "full overlap", set: []string{"a", "b"}, exclude: []string{"a", "b"}, want: nil},
+		{name: "partial overlap", set: []string{"a", "b", "c"}, exclude: []string{"b"}, want: []string{"a", "c"}},
+		{name: "empty set", set: nil, exclude: []string{"a"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, stringsNotIn(tt.set, tt.exclude))
+		})
+	}
+}
+
+func TestTraitsNotIn(t *testing.T) {
+	set := map[string][]string{
+		"logins":   {"root", "admin"},
+		"aws_role": {"viewer"},
+	}
+	exclude := map[string][]string{
+		"logins": {"root"},
+	}
+
+	got := traitsNotIn(set, exclude)
+	require.Equal(t, map[string][]string{
+		"logins":   {"admin"},
+		"aws_role": {"viewer"},
+	}, got)
+}
+
+func TestTraitsNotIn_NoDiff(t *testing.T) {
+	set := map[string][]string{"logins": {"root"}}
+	got := traitsNotIn(set, set)
+	require.Empty(t, got)
+}
+
+func TestDiffRolesAndTraits(t *testing.T) {
+	prior := newTestUserLoginStateWithRolesAndTraits(t, "alice", []string{"access", "auditor"}, map[string][]string{
+		"logins": {"root", "ubuntu"},
+	})
+	updated := newTestUserLoginStateWithRolesAndTraits(t, "alice", []string{"access", "admin"}, map[string][]string{
+		"logins": {"ubuntu", "ec2-user"},
+	})
+
+	diff := diffRolesAndTraits(prior, updated)
+	require.Equal(t, []string{"admin"}, diff.addedRoles)
+	require.Equal(t, []string{"auditor"}, diff.removedRoles)
+	require.Equal(t, map[string][]string{"logins": {"ec2-user"}}, diff.addedTraits)
+	require.Equal(t, map[string][]string{"logins": {"root"}}, diff.removedTraits)
+}
+
+func TestDiffRolesAndTraits_Create(t *testing.T) {
+	updated := newTestUserLoginStateWithRolesAndTraits(t, "alice", []string{"access"}, map[string][]string{
+		"logins": {"ubuntu"},
+	})
+
+	diff := diffRolesAndTraits(nil, updated)
+	require.Equal(t, []string{"access"}, diff.addedRoles)
+	require.Empty(t, diff.removedRoles)
+	require.Equal(t, map[string][]string{"logins": {"ubuntu"}}, diff.addedTraits)
+	require.Empty(t, diff.removedTraits)
+}
+
+func TestDiffRolesAndTraits_Delete(t *testing.T) {
+	prior := newTestUserLoginStateWithRolesAndTraits(t, "alice", []string{"access"}, map[string][]string{
+		"logins": {"ubuntu"},
+	})
+
+	diff := diffRolesAndTraits(prior, nil)
+	require.Empty(t, diff.addedRoles)
+	require.Equal(t, []string{"access"}, diff.removedRoles)
+	require.Empty(t, diff.addedTraits)
+	require.Equal(t, map[string][]string{"logins": {"ubuntu"}}, diff.removedTraits)
+}
+
+// pagedUserLoginStates serves a fixed slice of results back in fixed-size
+// pages, to drive listAllUserLoginStates without a real backend. Page tokens
+// are just the next start index, formatted as a string.
+func pagedUserLoginStates(all []types.UserLoginState, pageSize int) func(ctx context.Context, reqPageSize int, pageToken string) ([]types.UserLoginState, string, error) {
+	return func(_ context.Context, _ int, pageToken string) ([]types.UserLoginState, string, error) {
+		start := 0
+		if pageToken != "" {
+			parsed, err := strconv.Atoi(pageToken)
+			if err != nil {
+				return nil, "", trace.Wrap(err)
+			}
+			start = parsed
+		}
+
+		end := start + pageSize
+		if end > len(all) {
+			end = len(all)
+		}
+
+		next := ""
+		if end < len(all) {
+			next = strconv.Itoa(end)
+		}
+
+		return all[start:end], next, nil
+	}
+}
+
+func TestListAllUserLoginStates(t *testing.T) {
+	all := []types.UserLoginState{
+		newTestUserLoginState(t, "alice"),
+		newTestUserLoginState(t, "bob"),
+		newTestUserLoginState(t, "carol"),
+		newTestUserLoginState(t, "dave"),
+		newTestUserLoginState(t, "eve"),
+	}
+
+	var seen []string
+	err := listAllUserLoginStates(context.Background(), pagedUserLoginStates(all, 2), func(uls types.UserLoginState) {
+		seen = append(seen, uls.GetName())
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob", "carol", "dave", "eve"}, seen)
+}
+
+func TestListAllUserLoginStates_SinglePage(t *testing.T) {
+	all := []types.UserLoginState{newTestUserLoginState(t, "alice")}
+
+	var seen []string
+	err := listAllUserLoginStates(context.Background(), pagedUserLoginStates(all, 200), func(uls types.UserLoginState) {
+		seen = append(seen, uls.GetName())
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice"}, seen)
+}
+
+func TestListAllUserLoginStates_Empty(t *testing.T) {
+	var seen []string
+	err := listAllUserLoginStates(context.Background(), pagedUserLoginStates(nil, 200), func(uls types.UserLoginState) {
+		seen = append(seen, uls.GetName())
+	})
+	require.NoError(t, err)
+	require.Empty(t, seen)
+}
+
+func TestListAllUserLoginStates_PropagatesError(t *testing.T) {
+	boom := trace.ConnectionProblem(nil, "backend unavailable")
+	list := func(context.Context, int, string) ([]types.UserLoginState, string, error) {
+		return nil, "", boom
+	}
+
+	err := listAllUserLoginStates(context.Background(), list, func(types.UserLoginState) {})
+	require.True(t, trace.IsConnectionProblem(err))
+}
+
+func TestNameFilter(t *testing.T) {
+	t.Run("empty list matches everything", func(t *testing.T) {
+		want := nameFilter(nil)
+		require.True(t, want("alice"))
+		require.True(t, want("bob"))
+	})
+
+	t.Run("non-empty list matches only named users", func(t *testing.T) {
+		want := nameFilter([]string{"alice", "carol"})
+		require.True(t, want("alice"))
+		require.True(t, want("carol"))
+		require.False(t, want("bob"))
+	})
+}
+
+func TestToWatchEvent_Put(t *testing.T) {
+	uls := newTestUserLoginState(t, "alice")
+
+	op, proto, err := toWatchEvent(types.Event{Type: types.OpPut, Resource: uls})
+	require.NoError(t, err)
+	require.Equal(t, userloginstatev1.Op_OP_PUT, op)
+	require.Equal(t, "alice", proto.GetMetadata().GetName())
+}
+
+func TestToWatchEvent_Delete(t *testing.T) {
+	uls := newTestUserLoginState(t, "alice")
+
+	op, proto, err := toWatchEvent(types.Event{Type: types.OpDelete, Resource: uls})
+	require.NoError(t, err)
+	require.Equal(t, userloginstatev1.Op_OP_DELETE, op)
+	require.Equal(t, "alice", proto.GetMetadata().GetName())
+	require.Equal(t, types.KindUserLoginState, proto.GetKind())
+}
+
+func TestToWatchEvent_UnexpectedType(t *testing.T) {
+	_, _, err := toWatchEvent(types.Event{Type: types.OpInit})
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -0,0 +1,293 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/observability/metrics"
+)
+
+const (
+	// userLoginStateCacheTTL is the default per-entry TTL for cached
+	// GetUserLoginState lookups.
+	userLoginStateCacheTTL = 30 * time.Second
+
+	// userLoginStateCacheSize bounds the number of distinct users whose
+	// login state is cached at once.
+	userLoginStateCacheSize = 10_000
+)
+
+var (
+	userLoginStateCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "user_login_state",
+		Name:      "cache_hits_total",
+		Help:      "Number of GetUserLoginState calls served from cache",
+	})
+	userLoginStateCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "user_login_state",
+		Name:      "cache_misses_total",
+		Help:      "Number of GetUserLoginState calls that missed the cache and hit the backend",
+	})
+	userLoginStateCacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "teleport",
+		Subsystem: "user_login_state",
+		Name:      "cache_evictions_total",
+		Help:      "Number of entries evicted from the user login state cache, by TTL expiry or capacity",
+	})
+)
+
+func init() {
+	if err := metrics.RegisterPrometheusCollectors(
+		userLoginStateCacheHits,
+		userLoginStateCacheMisses,
+		userLoginStateCacheEvictions,
+	); err != nil {
+		panic(err)
+	}
+}
+
+// userLoginStateCacheEntry is the cached outcome of a GetUserLoginState call.
+// Caching the error alongside the result lets NotFound responses be served
+// from cache too (negative caching), which matters because callers probing
+// for a login state that doesn't exist would otherwise hit the backend on
+// every login.
+type userLoginStateCacheEntry struct {
+	uls types.UserLoginState
+	err error
+
+	// expiresAt is when this entry stops being served, measured against the
+	// CachingUserLoginStates' configured clock rather than the underlying
+	// LRU's own TTL. Checking it ourselves on every read lets a fake clock
+	// (set via CachingUserLoginStatesConfig.Clock) drive expiry in tests
+	// without sleeping on the wall clock.
+	expiresAt time.Time
+}
+
+// CachingUserLoginStatesConfig configures a CachingUserLoginStates.
+type CachingUserLoginStatesConfig struct {
+	// UserLoginStates is the backend service being wrapped.
+	UserLoginStates UserLoginStates
+
+	// TTL is how long a cached entry is considered fresh. Defaults to
+	// userLoginStateCacheTTL.
+	TTL time.Duration
+
+	// CacheSize bounds how many users' login states are cached at once.
+	// Defaults to userLoginStateCacheSize.
+	CacheSize int
+
+	// Clock is used to compute entry expiry. Defaults to the real clock; set
+	// this to a clockwork.FakeClock in tests to advance TTL expiry without
+	// sleeping.
+	Clock clockwork.Clock
+}
+
+func (c *CachingUserLoginStatesConfig) checkAndSetDefaults() error {
+	if c.UserLoginStates == nil {
+		return trace.BadParameter("user login states service is missing")
+	}
+
+	if c.TTL == 0 {
+		c.TTL = userLoginStateCacheTTL
+	}
+
+	if c.CacheSize == 0 {
+		c.CacheSize = userLoginStateCacheSize
+	}
+
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+
+	return nil
+}
+
+// CachingUserLoginStates is a read-through caching decorator around a
+// UserLoginStates implementation. It caches the result of GetUserLoginState
+// (including NotFound errors) behind a bounded LRU with a short per-entry
+// TTL, and collapses concurrent cache misses for the same user into a single
+// backend call. Writes made through this decorator go straight to the wrapped
+// backend and leave the cache untouched; entries are evicted only when a write
+// is reported via Invalidate*, after which reads observe it immediately.
+//
+// Construct one with NewCachingUserLoginStates and pass it as
+// userloginstate.ServiceConfig.UserLoginStates to put it in front of the gRPC
+// service.
+type CachingUserLoginStates struct {
+	UserLoginStates
+
+	cache *lru.LRU[string, *userLoginStateCacheEntry]
+	group singleflight.Group
+	clock clockwork.Clock
+	ttl   time.Duration
+
+	// mu guards epoch, which detects a write that lands while a
+	// GetUserLoginState call is in flight. Without this, a read that missed
+	// the cache just before a write could finish and re-seed the cache with
+	// the pre-write value after UpsertUserLoginState or DeleteUserLoginState
+	// has already invalidated it, leaving the stale entry to serve callers
+	// for a full TTL.
+	//
+	// epoch is a single counter for the whole cache rather than one per key:
+	// a per-key map would need its own eviction policy to stay bounded, and
+	// a username is exactly the kind of unbounded, attacker- or
+	// churn-influenced key space this cache exists to bound in the first
+	// place. Bumping one counter on every invalidation means a write to any
+	// user can make a concurrent read of a different user skip caching its
+	// result — an extra backend call at worst, never a correctness issue —
+	// in exchange for O(1) bookkeeping instead of a map that grows forever.
+	mu    sync.Mutex
+	epoch uint64
+}
+
+// NewCachingUserLoginStates creates a new read-through cache wrapping cfg.UserLoginStates.
+func NewCachingUserLoginStates(cfg CachingUserLoginStatesConfig) (*CachingUserLoginStates, error) {
+	if err := cfg.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	cache := lru.NewLRU[string, *userLoginStateCacheEntry](
+		cfg.CacheSize,
+		func(_ string, _ *userLoginStateCacheEntry) {
+			userLoginStateCacheEvictions.Inc()
+		},
+		cfg.TTL,
+	)
+
+	return &CachingUserLoginStates{
+		UserLoginStates: cfg.UserLoginStates,
+		cache:           cache,
+		clock:           cfg.Clock,
+		ttl:             cfg.TTL,
+	}, nil
+}
+
+// currentEpoch returns the write epoch in effect right now, so a read that
+// is about to start can later tell whether any write landed while it was in
+// flight.
+func (c *CachingUserLoginStates) currentEpoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+// addIfCurrent caches entry for name unless an invalidation has landed since
+// epoch was captured. This closes the race where a cache miss that started
+// before a write finishes, and would otherwise overwrite invalidateCache's
+// eviction with the stale pre-write value.
+func (c *CachingUserLoginStates) addIfCurrent(name string, entry *userLoginStateCacheEntry, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.epoch != epoch {
+		return
+	}
+	c.cache.Add(name, entry)
+}
+
+// GetUserLoginState returns the named user login state, serving from cache
+// when possible.
+func (c *CachingUserLoginStates) GetUserLoginState(ctx context.Context, name string) (types.UserLoginState, error) {
+	if entry, ok := c.cache.Get(name); ok {
+		if c.clock.Now().Before(entry.expiresAt) {
+			userLoginStateCacheHits.Inc()
+			if entry.err != nil {
+				return nil, trace.Wrap(entry.err)
+			}
+			return entry.uls, nil
+		}
+		// The entry is fresh as far as the LRU's own (real-time) TTL is
+		// concerned, but stale per the configured clock — only possible in
+		// tests, where that clock is fake and was advanced past ttl without
+		// waiting on the wall clock. Evict it and fall through to a miss.
+		c.cache.Remove(name)
+	}
+
+	userLoginStateCacheMisses.Inc()
+
+	epoch := c.currentEpoch()
+
+	entry, err, _ := c.group.Do(name, func() (any, error) {
+		uls, err := c.UserLoginStates.GetUserLoginState(ctx, name)
+		if err != nil && !trace.IsNotFound(err) {
+			// Only nil and NotFound are cacheable outcomes. Caching any
+			// other error (timeouts, backend unavailability, etc.) would
+			// turn a transient blip into a hard failure replayed to every
+			// caller for the rest of the TTL.
+			return nil, trace.Wrap(err)
+		}
+		entry := &userLoginStateCacheEntry{uls: uls, err: err, expiresAt: c.clock.Now().Add(c.ttl)}
+		c.addIfCurrent(name, entry, epoch)
+		return entry, nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	cached := entry.(*userLoginStateCacheEntry)
+	if cached.err != nil {
+		return nil, trace.Wrap(cached.err)
+	}
+	return cached.uls, nil
+}
+
+// GetUserLoginStateUncached reads straight through to the wrapped backend,
+// bypassing the cache entirely. Callers that need the true current state —
+// for example userloginstate.Service computing an audit-event diff around a
+// write — must use this instead of GetUserLoginState, since a cache hit
+// could otherwise return a value up to TTL seconds stale.
+func (c *CachingUserLoginStates) GetUserLoginStateUncached(ctx context.Context, name string) (types.UserLoginState, error) {
+	return c.UserLoginStates.GetUserLoginState(ctx, name)
+}
+
+// Upsert and Delete calls pass straight through to the wrapped
+// UserLoginStates via struct embedding; this decorator only caches reads.
+// Invalidation of affected entries is the caller's responsibility — see
+// InvalidateUserLoginState and InvalidateAllUserLoginStates, which
+// userloginstate.Service calls after a successful write.
+
+// InvalidateUserLoginState evicts the cache entry for the given user, if
+// any. Safe to call even if the user was never cached.
+func (c *CachingUserLoginStates) InvalidateUserLoginState(name string) {
+	c.mu.Lock()
+	c.epoch++
+	c.mu.Unlock()
+
+	c.cache.Remove(name)
+}
+
+// InvalidateAllUserLoginStates evicts every cache entry.
+func (c *CachingUserLoginStates) InvalidateAllUserLoginStates() {
+	c.mu.Lock()
+	c.epoch++
+	c.mu.Unlock()
+
+	c.cache.Purge()
+}
@@ -0,0 +1,238 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// fakeUserLoginStates is a minimal, in-memory UserLoginStates backend used to
+// exercise CachingUserLoginStates without a real store. beforeGet, when set,
+// runs synchronously before every GetUserLoginState lookup reaches the
+// backend map, letting tests inject delay or other backends' writes mid-read.
+type fakeUserLoginStates struct {
+	mu        sync.Mutex
+	states    map[string]types.UserLoginState
+	gets      int
+	beforeGet func()
+}
+
+func newFakeUserLoginStates() *fakeUserLoginStates {
+	return &fakeUserLoginStates{states: make(map[string]types.UserLoginState)}
+}
+
+func (f *fakeUserLoginStates) GetUserLoginState(ctx context.Context, name string) (types.UserLoginState, error) {
+	if f.beforeGet != nil {
+		f.beforeGet()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+
+	uls, ok := f.states[name]
+	if !ok {
+		return nil, trace.NotFound("user login state %q not found", name)
+	}
+	return uls, nil
+}
+
+func (f *fakeUserLoginStates) GetUserLoginStates(ctx context.Context) ([]types.UserLoginState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]types.UserLoginState, 0, len(f.states))
+	for _, uls := range f.states {
+		out = append(out, uls)
+	}
+	return out, nil
+}
+
+func (f *fakeUserLoginStates) ListUserLoginStates(ctx context.Context, pageSize int, pageToken string, filter UserLoginStatesFilter) ([]types.UserLoginState, string, error) {
+	all, _ := f.GetUserLoginStates(ctx)
+	return all, "", nil
+}
+
+func (f *fakeUserLoginStates) UpsertUserLoginState(ctx context.Context, uls types.UserLoginState) (types.UserLoginState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[uls.GetName()] = uls
+	return uls, nil
+}
+
+func (f *fakeUserLoginStates) DeleteUserLoginState(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.states, name)
+	return nil
+}
+
+func (f *fakeUserLoginStates) DeleteAllUserLoginStates(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states = make(map[string]types.UserLoginState)
+	return nil
+}
+
+func newTestUserLoginState(t *testing.T, name string, roles []string) types.UserLoginState {
+	t.Helper()
+	uls, err := types.NewUserLoginState(types.Metadata{Name: name}, types.UserLoginStateSpec{Roles: roles})
+	require.NoError(t, err)
+	return uls
+}
+
+func TestCachingUserLoginStates_TTLExpiry(t *testing.T) {
+	backend := newFakeUserLoginStates()
+	uls := newTestUserLoginState(t, "alice", []string{"access"})
+	_, err := backend.UpsertUserLoginState(context.Background(), uls)
+	require.NoError(t, err)
+
+	clock := clockwork.NewFakeClock()
+	ttl := 30 * time.Second
+	cache, err := NewCachingUserLoginStates(CachingUserLoginStatesConfig{
+		UserLoginStates: backend,
+		TTL:             ttl,
+		Clock:           clock,
+	})
+	require.NoError(t, err)
+
+	_, err = cache.GetUserLoginState(context.Background(), "alice")
+	require.NoError(t, err)
+	_, err = cache.GetUserLoginState(context.Background(), "alice")
+	require.NoError(t, err)
+
+	backend.mu.Lock()
+	gets := backend.gets
+	backend.mu.Unlock()
+	require.Equal(t, 1, gets, "second call within the TTL should be served from cache")
+
+	clock.Advance(ttl + time.Second)
+
+	_, err = cache.GetUserLoginState(context.Background(), "alice")
+	require.NoError(t, err)
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	require.Equal(t, 2, backend.gets, "expired entry should hit the backend again")
+}
+
+func TestCachingUserLoginStates_NegativeCaching(t *testing.T) {
+	backend := newFakeUserLoginStates()
+
+	cache, err := NewCachingUserLoginStates(CachingUserLoginStatesConfig{
+		UserLoginStates: backend,
+	})
+	require.NoError(t, err)
+
+	_, err = cache.GetUserLoginState(context.Background(), "ghost")
+	require.True(t, trace.IsNotFound(err))
+
+	_, err = cache.GetUserLoginState(context.Background(), "ghost")
+	require.True(t, trace.IsNotFound(err))
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	require.Equal(t, 1, backend.gets, "NotFound should be cached and served without hitting the backend again")
+}
+
+// errBackend is an UserLoginStates whose GetUserLoginState always fails with
+// a non-NotFound error, to verify that CachingUserLoginStates never caches
+// transient backend failures.
+type errBackend struct {
+	*fakeUserLoginStates
+	gets int
+}
+
+func (e *errBackend) GetUserLoginState(ctx context.Context, name string) (types.UserLoginState, error) {
+	e.gets++
+	return nil, trace.ConnectionProblem(nil, "backend unavailable")
+}
+
+func TestCachingUserLoginStates_DoesNotCacheTransientErrors(t *testing.T) {
+	backend := &errBackend{fakeUserLoginStates: newFakeUserLoginStates()}
+
+	cache, err := NewCachingUserLoginStates(CachingUserLoginStatesConfig{
+		UserLoginStates: backend,
+	})
+	require.NoError(t, err)
+
+	_, err = cache.GetUserLoginState(context.Background(), "alice")
+	require.True(t, trace.IsConnectionProblem(err))
+
+	_, err = cache.GetUserLoginState(context.Background(), "alice")
+	require.True(t, trace.IsConnectionProblem(err))
+
+	require.Equal(t, 2, backend.gets, "a transient error must never be served from cache")
+}
+
+func TestCachingUserLoginStates_InvalidateRace(t *testing.T) {
+	backend := newFakeUserLoginStates()
+	uls := newTestUserLoginState(t, "alice", []string{"access"})
+	_, err := backend.UpsertUserLoginState(context.Background(), uls)
+	require.NoError(t, err)
+
+	cache, err := NewCachingUserLoginStates(CachingUserLoginStatesConfig{
+		UserLoginStates: backend,
+	})
+	require.NoError(t, err)
+
+	// Block the in-flight read inside the backend call, after the cache has
+	// already captured the pre-write epoch, and release it only once the
+	// invalidating write has completed. entered is closed once the reader
+	// goroutine is actually parked in beforeGet, so the main goroutine can't
+	// race ahead and close release before the read has started.
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	backend.beforeGet = func() {
+		close(entered)
+		<-release
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var readErr error
+	var read types.UserLoginState
+	go func() {
+		defer wg.Done()
+		read, readErr = cache.GetUserLoginState(context.Background(), "alice")
+	}()
+
+	<-entered
+
+	updated := newTestUserLoginState(t, "alice", []string{"access", "admin"})
+	_, err = backend.UpsertUserLoginState(context.Background(), updated)
+	require.NoError(t, err)
+	cache.InvalidateUserLoginState("alice")
+
+	close(release)
+	wg.Wait()
+	require.NoError(t, readErr)
+	require.Equal(t, []string{"access"}, read.GetRoles(), "in-flight read should still return the pre-write value it fetched")
+
+	_, ok := cache.cache.Get("alice")
+	require.False(t, ok, "the in-flight read must not re-seed the cache after an invalidate landed while it was outstanding")
+}